@@ -1,19 +1,13 @@
 package coda
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
-	"io/ioutil"
-	"log"
 	"net/http"
-	"os"
-	"os/signal"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/spdd/coda-go-client/client/types"
-	"golang.org/x/net/websocket"
+	"github.com/spdd/coda-go-client/hub"
 )
 
 var subscriptionEventsQueries = map[string]string{
@@ -27,17 +21,27 @@ type Client struct {
 	SubscriptionEvents map[string]*types.Event
 	httpClient         *http.Client
 	Endpoint           string
-	hub                *Hub
+	hub                *hub.Hub
+
+	// wsSubs holds the state for the Client's single, multiplexed
+	// subscription connection. It is created lazily by ws(), guarded by
+	// wsSubsMu so a concurrent Close can't race its creation.
+	wsSubsMu sync.Mutex
+	wsSubs   *wsSubscriptions
 }
 
+// defaultEventBufferSize bounds an Event's Response channel, matching
+// package hub's default per-subscriber buffer. dispatchFrame drops the
+// oldest buffered frame rather than blocking once it's full.
+const defaultEventBufferSize = 64
+
 func createEvent(t string) *types.Event {
 	return &types.Event{
-		Response:    make(chan *types.ResponseData),
-		Type:        t,
-		Query:       subscriptionEventsQueries[t],
-		Unsubscribe: make(chan bool),
-		Subscribed:  false,
-		Count:       0,
+		Response:   make(chan *types.ResponseData, defaultEventBufferSize),
+		Type:       t,
+		Query:      subscriptionEventsQueries[t],
+		Subscribed: false,
+		Count:      0,
 	}
 }
 
@@ -51,8 +55,16 @@ func (c *Client) getEvent(t string) *types.Event {
 	}
 }
 
+// Event returns the Client's Event for subscription type t, creating it if
+// this is the first time it's been asked for. It exists so subsystems
+// built on top of Client, like follower.Follower, can read an Event's
+// Response channel without duplicating the subscription it represents.
+func (c *Client) Event(t string) *types.Event {
+	return c.getEvent(t)
+}
+
 // NewClient create new client object
-func NewClient(endpoint string, hub *Hub, eventsIt []string) *Client {
+func NewClient(endpoint string, hub *hub.Hub, eventsIt []string) *Client {
 	timeout := time.Duration(5 * time.Second)
 	httpClient := &http.Client{
 		Timeout: timeout,
@@ -71,7 +83,7 @@ func NewClient(endpoint string, hub *Hub, eventsIt []string) *Client {
 }
 
 // NewClient with http client
-func NewClientWith(client *http.Client, endpoint string, hub *Hub, eventsIt []string) *Client {
+func NewClientWith(client *http.Client, endpoint string, hub *hub.Hub, eventsIt []string) *Client {
 	subEvents := make(map[string]*types.Event)
 	for _, item := range eventsIt {
 		event := createEvent(item)
@@ -85,153 +97,24 @@ func NewClientWith(client *http.Client, endpoint string, hub *Hub, eventsIt []st
 	}
 }
 
-// Request HTTP request helper
-func (c *Client) makeHttpRequest(query string, variables interface{}) (string, error) {
-	payload, err := json.Marshal(map[string]string{
-		"query": query,
-	})
-
-	if variables != "" {
-		type Payload struct {
-			Query     string      `json:"query"`
-			Variables interface{} `json:"variables"`
-		}
-		p := Payload{
-			Query:     query,
-			Variables: variables,
-		}
-		payload, err = json.Marshal(p)
-		if err != nil {
-			log.Println(err)
-		}
-	}
-	//log.Println(bytes.NewBuffer(payload))
-	request, err := http.NewRequest("POST", c.Endpoint, bytes.NewBuffer(payload))
-	request.Header.Set("Content-Type", "application/json")
-	if err != nil {
-		log.Fatalln(err)
-	}
-
-	resp, err := c.httpClient.Do(request)
-	if err != nil {
-		log.Fatalln(err)
-	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatalln(err)
-	}
-	return string(body), nil
-}
-
-func getResponse(c *Client, query string, variables interface{}, ch chan *types.AbstractHttpResult) (*types.AbstractHttpResult, error) {
-	response, err := c.makeHttpRequest(query, variables)
-	if err != nil {
-		if ch != nil {
-			ch <- nil
-		}
-		return nil, err
-	}
-	var ds types.AbstractHttpResult
-	response = removeFromJsonString(response)
-	//log.Println("Result Abstract2:", response)
-	r := bytes.NewReader([]byte(response))
-	err2 := json.NewDecoder(r).Decode(&ds)
-	if err2 != nil {
-		if ch != nil {
-			ch <- nil
-		}
-		log.Println(err2)
-		return nil, err2
-	}
-	if ch != nil {
-		ch <- &ds
-		close(ch)
-	}
-	return &ds, nil
+// getUniversal runs a GraphQL query/mutation against the daemon and
+// returns it in the legacy, stringly-typed AbstractHttpResult shape.
+// Prefer the Context-suffixed, typed methods below for new code; this (and
+// getUniversalCh) exist only so the original method signatures keep working.
+func (c *Client) getUniversal(query string, variables interface{}) (*types.AbstractHttpResult, error) {
+	return toAbstractResult(c.doRequest(context.Background(), query, variables))
 }
 
 func (c *Client) getUniversalCh(query string, variables interface{}) <-chan *types.AbstractHttpResult {
 	ch := make(chan *types.AbstractHttpResult, 1)
 	go func() {
-		getResponse(c, query, variables, ch)
+		result, _ := c.getUniversal(query, variables)
+		ch <- result
+		close(ch)
 	}()
 	return ch
 }
 
-// GraphQL http/s query
-func (c *Client) getUniversal(query string, variables interface{}) (*types.AbstractHttpResult, error) {
-	return getResponse(c, query, variables, nil)
-}
-
-func (c *Client) subscribe(ctx context.Context, event *types.Event) {
-	if event == nil {
-		log.Println("Event is nil")
-		return
-	}
-	defer func() {
-		log.Println("Exit Subscribtion: ", event.Type)
-	}()
-	for {
-		select {
-		default:
-			event.Subscribed = true
-			url := strings.Replace(c.Endpoint, "http", `ws`, -1)
-			interrupt := make(chan os.Signal, 1)
-			signal.Notify(interrupt, os.Interrupt)
-
-			log.Printf("connecting to %s", url)
-			origin := "http://localhost/"
-			conn, err := websocket.Dial(url, "", origin)
-			if err != nil {
-				log.Println("dial:", err)
-				return
-			}
-
-			log.Printf("Subscription Type: %s", event.Type)
-			d2 := types.SubscribeDataQuery{
-				Type:    "start",
-				Id:      "1",
-				Payload: types.SubscribeQuery{Query: event.Query},
-			}
-			// send message
-			err2 := websocket.JSON.Send(conn, d2)
-			if err2 != nil {
-				log.Println("websocket.JSON.", err2)
-			}
-
-			var m types.SubscriptionResponse
-			// receive message
-			// messageType initializes some type of message
-			err3 := websocket.JSON.Receive(conn, &m)
-			if err3 != nil {
-				log.Println("Error Receive", err3)
-			}
-			conn.Close()
-			log.Println("Receive type:", m.Type)
-
-			responseData := &types.ResponseData{
-				Host: c.Endpoint,
-				Type: event.Type,
-				Data: &m,
-			}
-			event.Count += 1
-			if c.hub == nil {
-				event.Response <- responseData
-			} else {
-				c.hub.SubscriptionData <- responseData
-			}
-			time.Sleep(1 * time.Second)
-		case <-event.Unsubscribe:
-			log.Printf("%s unsubscribed from %s", c.Endpoint, event.Type)
-			event.Unsubscribe <- true
-			return
-		case <-ctx.Done():
-			return
-		}
-	}
-}
-
 // Coda API
 // GetDaemonStatus
 
@@ -330,19 +213,24 @@ func (c *Client) GetCurrentSnarkWorker() (*types.AbstractHttpResult, error) {
 }
 
 // Subscription API
+//
+// Each of these registers its Event with the Client's single multiplexed
+// subscription connection (dialled lazily on first use) and returns an
+// error channel that receives at most one value, if the subscription could
+// not be established.
 
-func (c *Client) SubscribeForEvent(ctx context.Context, event *types.Event) {
-	c.subscribe(ctx, event)
+func (c *Client) SubscribeForEvent(ctx context.Context, event *types.Event) <-chan error {
+	return c.subscribe(ctx, event)
 }
 
-func (c *Client) SubscribeForNewBlocks(ctx context.Context) {
-	c.subscribe(ctx, c.getEvent("NewBlock"))
+func (c *Client) SubscribeForNewBlocks(ctx context.Context) <-chan error {
+	return c.subscribe(ctx, c.getEvent("NewBlock"))
 }
 
-func (c *Client) SubscribeForSyncUpdates(ctx context.Context) {
-	c.subscribe(ctx, c.getEvent("SyncUpdate"))
+func (c *Client) SubscribeForSyncUpdates(ctx context.Context) <-chan error {
+	return c.subscribe(ctx, c.getEvent("SyncUpdate"))
 }
 
-func (c *Client) SubscribeForBlockConfirmations(ctx context.Context) {
-	c.subscribe(ctx, c.getEvent("BlockConfirmation"))
+func (c *Client) SubscribeForBlockConfirmations(ctx context.Context) <-chan error {
+	return c.subscribe(ctx, c.getEvent("BlockConfirmation"))
 }
@@ -0,0 +1,15 @@
+package coda
+
+import (
+	"context"
+
+	"github.com/spdd/coda-go-client/follower"
+)
+
+// Follow builds a reorg-safe view of the chain on top of c's block
+// subscriptions and delivers BlockAdded/BlockReverted/BlockFinalized
+// events to handler until ctx is cancelled or a subscription errors out.
+// See the follower package for Config and Store.
+func (c *Client) Follow(ctx context.Context, cfg follower.Config, handler follower.Handler) error {
+	return follower.New(c, cfg).Run(ctx, handler)
+}
@@ -0,0 +1,339 @@
+package coda
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/spdd/coda-go-client/client/types"
+)
+
+// defaultRequestTimeout is applied to requests made through a Context that
+// carries no deadline of its own, matching the Client's original hard-coded
+// 5s timeout.
+const defaultRequestTimeout = 5 * time.Second
+
+type graphQLRequest struct {
+	Query     string      `json:"query"`
+	Variables interface{} `json:"variables,omitempty"`
+}
+
+type graphQLEnvelope struct {
+	Data   json.RawMessage      `json:"data"`
+	Errors []types.GraphQLError `json:"errors,omitempty"`
+}
+
+// doRequest executes a GraphQL query against the daemon, honoring ctx's
+// deadline and cancellation. If ctx carries no deadline,
+// defaultRequestTimeout is applied. The http.Client's own Timeout still
+// applies as well (5s for a Client built with NewClient), so a longer ctx
+// deadline does not extend a request past it. Transport and decode
+// failures are returned as errors rather than killing the process via
+// log.Fatalln; a non-empty GraphQL "errors" array is returned as
+// types.GraphQLErrors alongside the (still valid) envelope.
+func (c *Client) doRequest(ctx context.Context, query string, variables interface{}) (*graphQLEnvelope, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultRequestTimeout)
+		defer cancel()
+	}
+
+	payload := map[string]interface{}{"query": query}
+	if s, isString := variables.(string); variables != nil && (!isString || s != "") {
+		payload["variables"] = variables
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("coda: marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("coda: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("coda: do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("coda: read response: %w", err)
+	}
+
+	var env graphQLEnvelope
+	if err := json.Unmarshal(respBody, &env); err != nil {
+		return nil, fmt.Errorf("coda: decode response: %w", err)
+	}
+	if len(env.Errors) > 0 {
+		return &env, types.GraphQLErrors(env.Errors)
+	}
+	return &env, nil
+}
+
+// decode unmarshals a successful envelope's "data" field into dst, which
+// should be a pointer to a struct whose single field is tagged with the
+// GraphQL operation's top-level key (e.g. `json:"daemonStatus"`).
+func decode(env *graphQLEnvelope, dst interface{}) error {
+	if env == nil || env.Data == nil {
+		return nil
+	}
+	return json.Unmarshal(env.Data, dst)
+}
+
+// toAbstractResult adapts a graphQLEnvelope to the legacy, stringly-typed
+// AbstractHttpResult shape used by the Client's original (non-Context)
+// methods.
+func toAbstractResult(env *graphQLEnvelope, err error) (*types.AbstractHttpResult, error) {
+	if _, ok := err.(types.GraphQLErrors); !ok && err != nil {
+		return nil, err
+	}
+	result := &types.AbstractHttpResult{}
+	if env != nil {
+		if len(env.Data) > 0 {
+			if decodeErr := json.Unmarshal(env.Data, &result.Data); decodeErr != nil {
+				return nil, fmt.Errorf("coda: decode response: %w", decodeErr)
+			}
+		}
+		result.Errors = env.Errors
+	}
+	return result, nil
+}
+
+// GetDaemonStatusContext fetches the daemon's current status.
+func (c *Client) GetDaemonStatusContext(ctx context.Context) (*types.DaemonStatus, error) {
+	env, err := c.doRequest(ctx, types.DaemonStatusQuery, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		DaemonStatus *types.DaemonStatus `json:"daemonStatus"`
+	}
+	if err := decode(env, &out); err != nil {
+		return nil, fmt.Errorf("coda: decode daemon status: %w", err)
+	}
+	return out.DaemonStatus, nil
+}
+
+// GetBestChainContext fetches up to maxLength blocks of the daemon's
+// current best chain, oldest first.
+func (c *Client) GetBestChainContext(ctx context.Context, maxLength int) ([]types.ChainBlock, error) {
+	type bestChain struct {
+		MaxLength int `json:"maxLength"`
+	}
+	env, err := c.doRequest(ctx, types.BestChainQuery, bestChain{MaxLength: maxLength})
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		BestChain []types.ChainBlock `json:"bestChain"`
+	}
+	if err := decode(env, &out); err != nil {
+		return nil, fmt.Errorf("coda: decode best chain: %w", err)
+	}
+	return out.BestChain, nil
+}
+
+// GetDaemonVersionContext fetches the daemon's version string.
+func (c *Client) GetDaemonVersionContext(ctx context.Context) (string, error) {
+	env, err := c.doRequest(ctx, types.DaemonVersionQuery, nil)
+	if err != nil {
+		return "", err
+	}
+	var out struct {
+		Version string `json:"version"`
+	}
+	if err := decode(env, &out); err != nil {
+		return "", fmt.Errorf("coda: decode daemon version: %w", err)
+	}
+	return out.Version, nil
+}
+
+// GetSyncStatusContext fetches the daemon's sync status.
+func (c *Client) GetSyncStatusContext(ctx context.Context) (string, error) {
+	env, err := c.doRequest(ctx, types.GetSyncStatusQuery, nil)
+	if err != nil {
+		return "", err
+	}
+	var out struct {
+		SyncStatus string `json:"syncStatus"`
+	}
+	if err := decode(env, &out); err != nil {
+		return "", fmt.Errorf("coda: decode sync status: %w", err)
+	}
+	return out.SyncStatus, nil
+}
+
+// GetWalletsContext fetches all wallets owned by the daemon.
+func (c *Client) GetWalletsContext(ctx context.Context) ([]types.Wallet, error) {
+	env, err := c.doRequest(ctx, types.GetWalletsQuery, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		OwnedWallets []types.Wallet `json:"ownedWallets"`
+	}
+	if err := decode(env, &out); err != nil {
+		return nil, fmt.Errorf("coda: decode wallets: %w", err)
+	}
+	return out.OwnedWallets, nil
+}
+
+// GetWalletContext fetches a single wallet by public key.
+func (c *Client) GetWalletContext(ctx context.Context, pk string) (*types.Wallet, error) {
+	type publicKey struct {
+		Pk string `json:"publicKey"`
+	}
+	env, err := c.doRequest(ctx, types.GetWalletQuery, publicKey{Pk: pk})
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		Wallet *types.Wallet `json:"wallet"`
+	}
+	if err := decode(env, &out); err != nil {
+		return nil, fmt.Errorf("coda: decode wallet: %w", err)
+	}
+	return out.Wallet, nil
+}
+
+// UnlockWalletContext unlocks a wallet with the given password.
+func (c *Client) UnlockWalletContext(ctx context.Context, pk, password string) (*types.Wallet, error) {
+	type unlockWallet struct {
+		Pk       string `json:"publicKey"`
+		Password string `json:"password"`
+	}
+	env, err := c.doRequest(ctx, types.UnlockWalletQuery, unlockWallet{Pk: pk, Password: password})
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		UnlockWallet *types.Wallet `json:"unlockWallet"`
+	}
+	if err := decode(env, &out); err != nil {
+		return nil, fmt.Errorf("coda: decode unlock wallet: %w", err)
+	}
+	return out.UnlockWallet, nil
+}
+
+// CreateWalletContext creates a new wallet protected by the given password.
+func (c *Client) CreateWalletContext(ctx context.Context, password string) (*types.Wallet, error) {
+	type createWallet struct {
+		Password string `json:"password"`
+	}
+	env, err := c.doRequest(ctx, types.CreateWalletQuery, createWallet{Password: password})
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		AddWallet *types.Wallet `json:"addWallet"`
+	}
+	if err := decode(env, &out); err != nil {
+		return nil, fmt.Errorf("coda: decode create wallet: %w", err)
+	}
+	return out.AddWallet, nil
+}
+
+// SendPaymentContext submits a payment transaction.
+func (c *Client) SendPaymentContext(ctx context.Context, from, to string, amount, fee int, memo string) (*types.Payment, error) {
+	type sendPayment struct {
+		From   string `json:"from"`
+		To     string `json:"to"`
+		Amount int    `json:"amount"`
+		Fee    int    `json:"fee"`
+		Memo   string `json:"memo"`
+	}
+	env, err := c.doRequest(ctx, types.SendPaymentQuery, sendPayment{From: from, To: to, Amount: amount, Fee: fee, Memo: memo})
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		SendPayment struct {
+			Payment *types.Payment `json:"payment"`
+		} `json:"sendPayment"`
+	}
+	if err := decode(env, &out); err != nil {
+		return nil, fmt.Errorf("coda: decode send payment: %w", err)
+	}
+	return out.SendPayment.Payment, nil
+}
+
+// GetPooledPaymentsContext fetches the payments currently in the
+// transaction pool for a public key.
+func (c *Client) GetPooledPaymentsContext(ctx context.Context, pk string) ([]types.PooledPayment, error) {
+	type publicKey struct {
+		Pk string `json:"publicKey"`
+	}
+	env, err := c.doRequest(ctx, types.GetPooledPaymentsQuery, publicKey{Pk: pk})
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		PooledUserCommands []types.PooledPayment `json:"pooledUserCommands"`
+	}
+	if err := decode(env, &out); err != nil {
+		return nil, fmt.Errorf("coda: decode pooled payments: %w", err)
+	}
+	return out.PooledUserCommands, nil
+}
+
+// GetTransactionStatusContext fetches the inclusion status of a previously
+// sent payment.
+func (c *Client) GetTransactionStatusContext(ctx context.Context, paymentId string) (types.TransactionStatus, error) {
+	type paymentID struct {
+		PaymentId string `json:"paymentId"`
+	}
+	env, err := c.doRequest(ctx, types.GetTransactionStatusQuery, paymentID{PaymentId: paymentId})
+	if err != nil {
+		return "", err
+	}
+	var out struct {
+		TransactionStatus types.TransactionStatus `json:"transactionStatus"`
+	}
+	if err := decode(env, &out); err != nil {
+		return "", fmt.Errorf("coda: decode transaction status: %w", err)
+	}
+	return out.TransactionStatus, nil
+}
+
+// SetSnarkWorkerContext sets the SNARK worker. Pass a nil workerPk to
+// disable the SNARK worker.
+func (c *Client) SetSnarkWorkerContext(ctx context.Context, workerPk interface{}, fee string) (*types.SnarkWorker, error) {
+	type snarkWorker struct {
+		WorkerPK interface{} `json:"worker_pk"`
+		Fee      string      `json:"fee"`
+	}
+	env, err := c.doRequest(ctx, types.SetSnarkWorkerQuery, snarkWorker{WorkerPK: workerPk, Fee: fee})
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		SetSnarkWorker *types.SnarkWorker `json:"setSnarkWorker"`
+	}
+	if err := decode(env, &out); err != nil {
+		return nil, fmt.Errorf("coda: decode set snark worker: %w", err)
+	}
+	return out.SetSnarkWorker, nil
+}
+
+// GetCurrentSnarkWorkerContext fetches the currently configured SNARK worker.
+func (c *Client) GetCurrentSnarkWorkerContext(ctx context.Context) (*types.SnarkWorker, error) {
+	env, err := c.doRequest(ctx, types.GetCurrentSnarkWorkerQuery, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out struct {
+		SnarkWorker *types.SnarkWorker `json:"snarkWorker"`
+	}
+	if err := decode(env, &out); err != nil {
+		return nil, fmt.Errorf("coda: decode current snark worker: %w", err)
+	}
+	return out.SnarkWorker, nil
+}
@@ -0,0 +1,10 @@
+package coda
+
+import "github.com/spdd/coda-go-client/payments"
+
+// Payments returns a payments.Service that sends payments through c,
+// unlocking wallets via keyring as needed. See the payments package for
+// Mina amounts, PaymentRequest and PendingPayment.
+func (c *Client) Payments(keyring payments.Keyring) *payments.Service {
+	return payments.NewService(c, keyring)
+}
@@ -0,0 +1,406 @@
+package coda
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/spdd/coda-go-client/client/types"
+)
+
+const (
+	wsPingInterval = 30 * time.Second
+	wsWriteTimeout = 10 * time.Second
+	wsMinBackoff   = 500 * time.Millisecond
+	wsMaxBackoff   = 30 * time.Second
+)
+
+// wsSubscriptions is the connection-multiplexing state for a Client's
+// subscription websocket. It is created lazily on the first call to
+// subscribe and lives for the lifetime of the Client: ctx/cancel give the
+// reconnect loop its own lifetime, independent of any one subscriber's ctx,
+// so one caller cancelling its ctx can't tear down the shared connection
+// out from under every other subscription. Close stops it.
+type wsSubscriptions struct {
+	mu     sync.Mutex
+	conn   *websocket.Conn
+	subs   map[string]*types.Event
+	nextID uint64
+	once   sync.Once
+
+	// pendingErrs holds the error channels of subscribe calls still
+	// waiting to learn the outcome of the next dial attempt, because
+	// there was no live connection to report success/failure off of
+	// immediately. notifyPending services (and clears) all of them every
+	// time a dial attempt resolves, not just the one call that happened
+	// to start the reconnect loop.
+	pendingErrs []chan<- error
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// notifyPending delivers err, if non-nil, to every subscribe call still
+// waiting on the outcome of the shared connection's next dial attempt, then
+// clears the list: each one is one-shot, so whether this attempt succeeded
+// or failed, they've all gotten their answer.
+func (ws *wsSubscriptions) notifyPending(err error) {
+	ws.mu.Lock()
+	pending := ws.pendingErrs
+	ws.pendingErrs = nil
+	ws.mu.Unlock()
+
+	if err == nil {
+		return
+	}
+	for _, ch := range pending {
+		select {
+		case ch <- err:
+		default:
+		}
+	}
+}
+
+func (c *Client) ws() *wsSubscriptions {
+	c.wsSubsMu.Lock()
+	defer c.wsSubsMu.Unlock()
+	if c.wsSubs == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.wsSubs = &wsSubscriptions{
+			subs:   make(map[string]*types.Event),
+			ctx:    ctx,
+			cancel: cancel,
+		}
+	}
+	return c.wsSubs
+}
+
+// Close stops the Client's shared subscription connection, if one was ever
+// started, and releases its reconnect-loop goroutine. It does not affect
+// non-subscription API calls. It is safe to call even if the Client was
+// never used for subscriptions.
+func (c *Client) Close() error {
+	c.wsSubsMu.Lock()
+	ws := c.wsSubs
+	c.wsSubsMu.Unlock()
+	if ws == nil {
+		return nil
+	}
+	ws.cancel()
+	return nil
+}
+
+// subscribe registers event with the Client's multiplexed subscription
+// connection, starting the connection (and its reconnect loop) on first
+// use. The reconnect loop runs for the lifetime of the Client (until
+// Close), not ctx: ctx only bounds this particular subscription, which is
+// unsubscribed automatically once ctx is done. subscribe returns an error
+// channel that receives a single value if the subscription cannot be
+// (re)established; callers that don't care may ignore it.
+func (c *Client) subscribe(ctx context.Context, event *types.Event) <-chan error {
+	errCh := make(chan error, 1)
+	if event == nil {
+		errCh <- fmt.Errorf("coda: subscribe called with a nil event")
+		return errCh
+	}
+
+	ws := c.ws()
+	if event.ID == "" {
+		event.ID = fmt.Sprintf("%d", atomic.AddUint64(&ws.nextID, 1))
+	}
+	event.Subscribed = true
+
+	ws.mu.Lock()
+	ws.subs[event.ID] = event
+	conn := ws.conn
+	if conn == nil {
+		// No live connection to report success/failure off of yet: wait
+		// for the next dial attempt's outcome, whichever subscribe call
+		// happens to be the one that triggers (or is already waiting on)
+		// it.
+		ws.pendingErrs = append(ws.pendingErrs, errCh)
+	}
+	ws.mu.Unlock()
+
+	ws.once.Do(func() {
+		go c.runSubscriptionLoop(ws.ctx)
+	})
+
+	select {
+	case <-ws.ctx.Done():
+		// The reconnect loop already exited (Close was called before this
+		// subscribe, possibly before the loop even started): nothing will
+		// ever drain pendingErrs for this call, so report it directly
+		// rather than leaving the caller's errCh hanging forever.
+		select {
+		case errCh <- fmt.Errorf("coda: subscribe called on a closed Client"):
+		default:
+		}
+	default:
+	}
+
+	if conn != nil {
+		if err := c.sendStart(conn, event); err != nil {
+			select {
+			case errCh <- err:
+			default:
+			}
+		}
+	}
+
+	if ctx != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				c.Unsubscribe(event)
+			case <-ws.ctx.Done():
+			}
+		}()
+	}
+
+	return errCh
+}
+
+// Unsubscribe stops an active subscription by sending a graphql-ws "stop"
+// frame rather than tearing down the shared socket.
+func (c *Client) Unsubscribe(event *types.Event) error {
+	if event == nil {
+		return fmt.Errorf("coda: Unsubscribe called with a nil event")
+	}
+	ws := c.ws()
+	ws.mu.Lock()
+	delete(ws.subs, event.ID)
+	conn := ws.conn
+	ws.mu.Unlock()
+
+	event.Subscribed = false
+	if conn == nil {
+		return nil
+	}
+	return c.writeFrame(conn, types.SubscribeDataQuery{Type: types.GQLStop, Id: event.ID})
+}
+
+func (c *Client) sendStart(conn *websocket.Conn, event *types.Event) error {
+	return c.writeFrame(conn, types.SubscribeDataQuery{
+		Type:    types.GQLStart,
+		Id:      event.ID,
+		Payload: types.SubscribeQuery{Query: event.Query},
+	})
+}
+
+func (c *Client) writeFrame(conn *websocket.Conn, frame interface{}) error {
+	ws := c.ws()
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if conn != ws.conn {
+		// Stale connection reference; the frame will be re-sent on the
+		// next (re)connect for "start" frames, and is a no-op otherwise.
+		return nil
+	}
+	conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return conn.WriteJSON(frame)
+}
+
+// runSubscriptionLoop owns the single websocket connection used by the
+// Client for every active subscription. It reconnects with exponential
+// backoff and jitter, re-issuing "start" frames for every subscription
+// still registered, and dispatches inbound "data" frames by subscription
+// id. Every dial attempt's outcome is reported to whichever subscribe
+// calls are currently waiting on one, via ws.notifyPending.
+func (c *Client) runSubscriptionLoop(ctx context.Context) {
+	ws := c.ws()
+	attempt := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		conn, err := c.dialSubscriptionConn(ctx)
+		if err != nil {
+			ws.notifyPending(err)
+			attempt++
+			if !sleepWithContext(ctx, backoff(attempt)) {
+				return
+			}
+			continue
+		}
+		attempt = 0
+
+		ws.mu.Lock()
+		ws.conn = conn
+		subs := make([]*types.Event, 0, len(ws.subs))
+		for _, event := range ws.subs {
+			subs = append(subs, event)
+		}
+		ws.mu.Unlock()
+		ws.notifyPending(nil)
+
+		if err := c.writeFrame(conn, types.SubscribeDataQuery{Type: types.GQLConnectionInit}); err != nil {
+			conn.Close()
+			continue
+		}
+		for _, event := range subs {
+			if err := c.sendStart(conn, event); err != nil {
+				log.Println("coda: resubscribe failed for", event.Type, err)
+			}
+		}
+
+		c.pumpConnection(ctx, conn)
+
+		ws.mu.Lock()
+		if ws.conn == conn {
+			ws.conn = nil
+		}
+		ws.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
+
+// pumpConnection reads frames off conn, dispatching them to the matching
+// Event, and pings the connection on an interval until it errors out or
+// ctx is cancelled.
+func (c *Client) pumpConnection(ctx context.Context, conn *websocket.Conn) {
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		ticker := time.NewTicker(wsPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ws := c.ws()
+				ws.mu.Lock()
+				stale := ws.conn != conn
+				ws.mu.Unlock()
+				if stale {
+					return
+				}
+				conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+				if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+					return
+				}
+			case <-done:
+				return
+			case <-ctx.Done():
+				// Unblock the ReadJSON below so Close actually tears the
+				// connection down instead of waiting on the next frame.
+				conn.Close()
+				return
+			}
+		}
+	}()
+
+	for {
+		var frame types.SubscriptionResponse
+		if err := conn.ReadJSON(&frame); err != nil {
+			conn.Close()
+			return
+		}
+		c.dispatchFrame(&frame)
+	}
+}
+
+func (c *Client) dispatchFrame(frame *types.SubscriptionResponse) {
+	switch frame.Type {
+	case types.GQLConnectionKeepAlive, types.GQLConnectionAck:
+		return
+	case types.GQLComplete:
+		ws := c.ws()
+		ws.mu.Lock()
+		delete(ws.subs, frame.Id)
+		ws.mu.Unlock()
+		return
+	}
+
+	ws := c.ws()
+	ws.mu.Lock()
+	event, ok := ws.subs[frame.Id]
+	ws.mu.Unlock()
+	if !ok {
+		return
+	}
+	event.Count++
+
+	responseData := &types.ResponseData{
+		Host: c.Endpoint,
+		Type: event.Type,
+		Data: frame,
+	}
+	if c.hub == nil {
+		deliver(event.Response, responseData)
+	} else {
+		deliver(c.hub.SubscriptionData, responseData)
+	}
+}
+
+// deliver sends data on ch without blocking. If ch is full, its oldest
+// buffered frame is dropped to make room first. This is the same
+// drop-oldest policy package hub applies to its own subscribers; applying
+// it here too means a single slow consumer (e.g. one that stopped draining
+// its Event.Response after its own ctx was cancelled) can never wedge the
+// shared read loop that every other subscription depends on.
+func deliver(ch chan *types.ResponseData, data *types.ResponseData) {
+	select {
+	case ch <- data:
+		return
+	default:
+	}
+	select {
+	case <-ch:
+	default:
+	}
+	select {
+	case ch <- data:
+	default:
+	}
+}
+
+func (c *Client) dialSubscriptionConn(ctx context.Context) (*websocket.Conn, error) {
+	url := strings.Replace(c.Endpoint, "http", "ws", -1)
+	dialer := websocket.Dialer{HandshakeTimeout: wsWriteTimeout}
+	conn, _, err := dialer.DialContext(ctx, url, nil)
+	return conn, err
+}
+
+func backoff(attempt int) time.Duration {
+	d := wsMinBackoff * time.Duration(1<<uint(attempt-1))
+	if d > wsMaxBackoff || d <= 0 {
+		d = wsMaxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(d) / 2))
+	return d/2 + jitter
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// decodeInto unmarshals a subscription data payload into dst. It is a
+// convenience for consumers of types.ResponseData that want a typed view
+// of frame.Data.Payload instead of the raw bytes.
+func decodeInto(frame *types.SubscriptionResponse, dst interface{}) error {
+	if frame == nil || frame.Payload == nil {
+		return fmt.Errorf("coda: empty subscription payload")
+	}
+	return json.Unmarshal(frame.Payload, dst)
+}
@@ -0,0 +1,107 @@
+package coda
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/spdd/coda-go-client/client/types"
+)
+
+// unreachableEndpoint is a loopback address nobody is listening on, so
+// dialSubscriptionConn fails fast with "connection refused" instead of
+// timing out.
+const unreachableEndpoint = "http://127.0.0.1:1"
+
+func TestSubscribeNotifiesAllPendingCallersOfDialFailure(t *testing.T) {
+	c := NewClient(unreachableEndpoint, nil, nil)
+	defer c.Close()
+
+	errCh1 := c.SubscribeForNewBlocks(context.Background())
+	time.Sleep(50 * time.Millisecond) // let the first subscribe start the reconnect loop
+	errCh2 := c.SubscribeForSyncUpdates(context.Background())
+
+	select {
+	case err := <-errCh1:
+		if err == nil {
+			t.Fatal("errCh1: expected a dial error, got nil")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("errCh1: timed out waiting for a dial error")
+	}
+
+	select {
+	case err := <-errCh2:
+		if err == nil {
+			t.Fatal("errCh2: expected a dial error, got nil")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("errCh2: timed out waiting for a dial error (subscribed after the loop already started)")
+	}
+}
+
+func TestSubscribeAfterCloseReportsAnErrorInsteadOfHanging(t *testing.T) {
+	c := NewClient(unreachableEndpoint, nil, nil)
+	errCh1 := c.SubscribeForNewBlocks(context.Background())
+	<-errCh1 // wait for the reconnect loop to actually start and fail once
+	c.Close()
+
+	errCh2 := c.SubscribeForSyncUpdates(context.Background())
+	select {
+	case err := <-errCh2:
+		if err == nil {
+			t.Fatal("expected an error for a subscribe after Close, got nil")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out: subscribe after Close left its errCh unserviced")
+	}
+}
+
+func TestBackoffIsBoundedAndPositive(t *testing.T) {
+	for attempt := 1; attempt <= 10; attempt++ {
+		d := backoff(attempt)
+		if d <= 0 || d > wsMaxBackoff {
+			t.Fatalf("backoff(%d) = %v, want in (0, %v]", attempt, d, wsMaxBackoff)
+		}
+	}
+}
+
+func TestSleepWithContextReturnsFalseWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if sleepWithContext(ctx, time.Second) {
+		t.Fatal("sleepWithContext should return false for an already-cancelled ctx")
+	}
+}
+
+func TestDispatchFrameDropsOldestWhenConsumerIsSlow(t *testing.T) {
+	c := NewClient(unreachableEndpoint, nil, nil)
+	event := createEvent("NewBlock")
+	event.ID = "1"
+	ws := c.ws()
+	ws.subs[event.ID] = event
+
+	total := cap(event.Response) + 5
+	for i := 0; i < total; i++ {
+		c.dispatchFrame(&types.SubscriptionResponse{
+			Type:    types.GQLData,
+			Id:      event.ID,
+			Payload: json.RawMessage(strconv.Itoa(i)),
+		})
+	}
+
+	if got := len(event.Response); got != cap(event.Response) {
+		t.Fatalf("event.Response has %d buffered frames, want %d (full, never blocked)", got, cap(event.Response))
+	}
+
+	first := <-event.Response
+	var firstPayload int
+	if err := json.Unmarshal(first.Data.Payload, &firstPayload); err != nil {
+		t.Fatalf("decode first buffered frame: %v", err)
+	}
+	if firstPayload < total-cap(event.Response) {
+		t.Fatalf("first buffered frame carries payload %d, want one of the later frames (the oldest ones should have been dropped)", firstPayload)
+	}
+}
@@ -0,0 +1,22 @@
+package types
+
+import "strings"
+
+// Error implements the error interface for a single GraphQL error entry.
+func (e GraphQLError) Error() string {
+	return e.Message
+}
+
+// GraphQLErrors is returned by Client request methods when the daemon's
+// response carries a non-empty top-level "errors" array. The underlying
+// transport request still succeeded; it is the GraphQL operation itself
+// that failed.
+type GraphQLErrors []GraphQLError
+
+func (errs GraphQLErrors) Error() string {
+	messages := make([]string, len(errs))
+	for i, e := range errs {
+		messages[i] = e.Message
+	}
+	return "coda: graphql errors: " + strings.Join(messages, "; ")
+}
@@ -0,0 +1,17 @@
+package types
+
+// GraphQLError mirrors a single entry in a GraphQL response's top-level
+// "errors" array.
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// AbstractHttpResult is the raw, stringly-typed shape of a GraphQL HTTP
+// response. It is kept around so older call sites that expect it keep
+// compiling; typed call sites should prefer the Context-suffixed methods.
+type AbstractHttpResult struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []GraphQLError         `json:"errors,omitempty"`
+}
@@ -0,0 +1,162 @@
+package types
+
+// DaemonStatusQuery fetches the daemon's current status.
+const DaemonStatusQuery = `
+query {
+  daemonStatus {
+    numAccounts
+    blockchainLength
+    highestBlockLengthReceived
+    uptimeSecs
+    ledgerMerkleRoot
+    stateHash
+    commitId
+    syncStatus
+    peers
+    txnFeesStats {
+      min
+      max
+      median
+    }
+  }
+}`
+
+// DaemonVersionQuery fetches the daemon's version string.
+const DaemonVersionQuery = `
+query {
+  version
+}`
+
+// GetSyncStatusQuery fetches the daemon's sync status.
+const GetSyncStatusQuery = `
+query {
+  syncStatus
+}`
+
+// GetWalletsQuery fetches all wallets owned by the daemon.
+const GetWalletsQuery = `
+query {
+  ownedWallets {
+    publicKey
+    balance {
+      total
+    }
+    nonce
+  }
+}`
+
+// GetWalletQuery fetches a single wallet by public key.
+const GetWalletQuery = `
+query($publicKey: PublicKey!) {
+  wallet(publicKey: $publicKey) {
+    publicKey
+    balance {
+      total
+    }
+    nonce
+  }
+}`
+
+// UnlockWalletQuery unlocks a wallet with the given password.
+const UnlockWalletQuery = `
+mutation($publicKey: PublicKey!, $password: String!) {
+  unlockWallet(input: { publicKey: $publicKey, password: $password }) {
+    publicKey
+  }
+}`
+
+// CreateWalletQuery creates a new wallet protected by the given password.
+const CreateWalletQuery = `
+mutation($password: String!) {
+  addWallet(input: { password: $password }) {
+    publicKey
+  }
+}`
+
+// SendPaymentQuery submits a payment transaction.
+const SendPaymentQuery = `
+mutation($from: PublicKey!, $to: PublicKey!, $amount: UInt64!, $fee: UInt64!, $memo: String) {
+  sendPayment(input: { from: $from, to: $to, amount: $amount, fee: $fee, memo: $memo }) {
+    payment {
+      id
+      nonce
+    }
+  }
+}`
+
+// GetPooledPaymentsQuery fetches the payments currently in the transaction pool for a public key.
+const GetPooledPaymentsQuery = `
+query($publicKey: PublicKey!) {
+  pooledUserCommands(publicKey: $publicKey) {
+    id
+    from
+    to
+    amount
+    fee
+    memo
+    nonce
+  }
+}`
+
+// GetTransactionStatusQuery fetches the inclusion status of a previously sent payment.
+const GetTransactionStatusQuery = `
+query($paymentId: ID!) {
+  transactionStatus(payment: $paymentId)
+}`
+
+// SetSnarkWorkerQuery sets (or disables, when workerPk is nil) the SNARK worker.
+const SetSnarkWorkerQuery = `
+mutation($worker_pk: PublicKey, $fee: UInt64!) {
+  setSnarkWorker(input: { publicKey: $worker_pk, fee: $fee }) {
+    lastSnarkWorker
+  }
+}`
+
+// GetCurrentSnarkWorkerQuery fetches the currently configured SNARK worker.
+const GetCurrentSnarkWorkerQuery = `
+query {
+  snarkWorker {
+    key
+    fee
+  }
+}`
+
+// BestChainQuery fetches up to maxLength blocks of the daemon's current
+// best chain, oldest first, for back-filling a follower's in-memory window.
+const BestChainQuery = `
+query($maxLength: Int) {
+  bestChain(maxLength: $maxLength) {
+    stateHash
+    protocolState {
+      previousStateHash
+      consensusState {
+        blockHeight
+      }
+    }
+  }
+}`
+
+// NewBlockSubscriptionQuery subscribes to newly produced blocks.
+const NewBlockSubscriptionQuery = `
+subscription {
+  newBlock {
+    stateHash
+    previousStateHash
+    blockchainLength
+  }
+}`
+
+// SyncUpdateSubscriptionQuery subscribes to daemon sync status changes.
+const SyncUpdateSubscriptionQuery = `
+subscription {
+  syncUpdate
+}`
+
+// BlockConfirmationSubscriptionQuery subscribes to confirmation depth updates for recent blocks.
+const BlockConfirmationSubscriptionQuery = `
+subscription {
+  newBlockConfirmation {
+    stateHash
+    numConfirmations
+  }
+}`
@@ -0,0 +1,80 @@
+package types
+
+// Balance is the nano-mina balance breakdown returned for a wallet.
+type Balance struct {
+	Total string `json:"total"`
+}
+
+// FeeStats summarizes recently observed transaction fees, in nanomina, and
+// is used to estimate a reasonable fee for a new payment.
+type FeeStats struct {
+	Min    string `json:"min"`
+	Max    string `json:"max"`
+	Median string `json:"median"`
+}
+
+// DaemonStatus is the decoded result of DaemonStatusQuery.
+type DaemonStatus struct {
+	NumAccounts                int       `json:"numAccounts"`
+	BlockchainLength           int       `json:"blockchainLength"`
+	HighestBlockLengthReceived int       `json:"highestBlockLengthReceived"`
+	UptimeSecs                 int       `json:"uptimeSecs"`
+	LedgerMerkleRoot           string    `json:"ledgerMerkleRoot"`
+	StateHash                  string    `json:"stateHash"`
+	CommitId                   string    `json:"commitId"`
+	SyncStatus                 string    `json:"syncStatus"`
+	TxnFeesStats               *FeeStats `json:"txnFeesStats,omitempty"`
+	Peers                      []string  `json:"peers"`
+}
+
+// Wallet is the decoded result of GetWalletQuery/GetWalletsQuery and the
+// response of CreateWalletQuery/UnlockWalletQuery.
+type Wallet struct {
+	PublicKey string  `json:"publicKey"`
+	Balance   Balance `json:"balance"`
+	Nonce     string  `json:"nonce"`
+}
+
+// Payment is the decoded payment handle returned by SendPaymentQuery.
+type Payment struct {
+	ID    string `json:"id"`
+	Nonce string `json:"nonce"`
+}
+
+// PooledPayment is a single payment sitting in the daemon's transaction pool.
+type PooledPayment struct {
+	ID     string `json:"id"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount string `json:"amount"`
+	Fee    string `json:"fee"`
+	Memo   string `json:"memo"`
+	Nonce  string `json:"nonce"`
+}
+
+// TransactionStatus is the inclusion state of a previously sent payment.
+type TransactionStatus string
+
+// Known TransactionStatus values, as returned by GetTransactionStatusQuery.
+const (
+	TransactionStatusPending  TransactionStatus = "PENDING"
+	TransactionStatusIncluded TransactionStatus = "INCLUDED"
+	TransactionStatusUnknown  TransactionStatus = "UNKNOWN"
+)
+
+// SnarkWorker is the daemon's currently configured SNARK worker.
+type SnarkWorker struct {
+	Key string `json:"key"`
+	Fee string `json:"fee"`
+}
+
+// ChainBlock is a single block as returned by BestChainQuery.
+type ChainBlock struct {
+	StateHash     string `json:"stateHash"`
+	ProtocolState struct {
+		PreviousStateHash string `json:"previousStateHash"`
+		ConsensusState    struct {
+			BlockHeight string `json:"blockHeight"`
+		} `json:"consensusState"`
+	} `json:"protocolState"`
+}
@@ -0,0 +1,60 @@
+package types
+
+import "encoding/json"
+
+// graphql-ws protocol message types. See
+// https://github.com/apollographql/subscriptions-transport-ws/blob/master/PROTOCOL.md
+const (
+	GQLConnectionInit      = "connection_init"
+	GQLConnectionAck       = "connection_ack"
+	GQLStart               = "start"
+	GQLData                = "data"
+	GQLError               = "error"
+	GQLComplete            = "complete"
+	GQLStop                = "stop"
+	GQLConnectionKeepAlive = "ka"
+)
+
+// SubscribeQuery is the payload of a "start" message.
+type SubscribeQuery struct {
+	Query     string      `json:"query"`
+	Variables interface{} `json:"variables,omitempty"`
+}
+
+// SubscribeDataQuery is a single graphql-ws protocol frame sent to the
+// daemon, e.g. a "connection_init", "start" or "stop" message.
+type SubscribeDataQuery struct {
+	Type    string      `json:"type"`
+	Id      string      `json:"id,omitempty"`
+	Payload interface{} `json:"payload,omitempty"`
+}
+
+// SubscriptionResponse is a single graphql-ws protocol frame received from
+// the daemon. Payload is left raw so it can be decoded once the frame's
+// subscription id has been matched back to the Event that requested it.
+type SubscriptionResponse struct {
+	Type    string          `json:"type"`
+	Id      string          `json:"id,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Event tracks a single active GraphQL subscription multiplexed over the
+// Client's websocket connection. Unsubscribing is done through
+// Client.Unsubscribe, which sends a graphql-ws "stop" frame for ID.
+type Event struct {
+	ID         string
+	Type       string
+	Query      string
+	Response   chan *ResponseData
+	Subscribed bool
+	Count      int
+}
+
+// ResponseData wraps a single inbound subscription data frame together with
+// the host and event type it came from, so that consumers fed by a shared
+// Hub can still tell subscriptions apart.
+type ResponseData struct {
+	Host string
+	Type string
+	Data *SubscriptionResponse
+}
@@ -0,0 +1,283 @@
+// Package follower builds a reorg-safe view of the chain on top of a
+// Client's block subscriptions, so wallets/explorers/payment processors
+// don't have to re-implement reorg handling themselves.
+package follower
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/spdd/coda-go-client/client/types"
+)
+
+const defaultWindowSize = 256
+
+// EventType identifies which transition a follower Event represents.
+type EventType int
+
+const (
+	// BlockAdded fires when a new block extends (or becomes) the tip.
+	BlockAdded EventType = iota
+	// BlockReverted fires for each block removed from the tip during a
+	// reorg, most recent first.
+	BlockReverted
+	// BlockFinalized fires once a block has been confirmed to at least
+	// Config.Confirmations depth.
+	BlockFinalized
+)
+
+// Block is the minimal per-block data the follower tracks.
+type Block struct {
+	Height     uint64
+	Hash       string
+	ParentHash string
+}
+
+// Event is delivered to a Handler for every chain transition the follower
+// observes.
+type Event struct {
+	Type  EventType
+	Block Block
+}
+
+// Handler processes follower Events. It is called synchronously from
+// Follower.Run's goroutine, so it should not block for long.
+type Handler func(Event)
+
+// Source is the subset of Client a Follower needs: subscribing to new
+// blocks and their confirmations, plus fetching the current best chain to
+// back-fill from. It is satisfied by *coda.Client.
+type Source interface {
+	SubscribeForNewBlocks(ctx context.Context) <-chan error
+	SubscribeForBlockConfirmations(ctx context.Context) <-chan error
+	Event(t string) *types.Event
+	GetBestChainContext(ctx context.Context, maxLength int) ([]types.ChainBlock, error)
+}
+
+// Config controls how a Follower tracks and confirms blocks.
+type Config struct {
+	// Confirmations is how many confirmations a block needs, as reported
+	// by the daemon's block-confirmation subscription, before it is
+	// considered finalized.
+	Confirmations uint64
+	// WindowSize is how many of the most recent blocks are kept in memory
+	// to detect reorgs against. Defaults to 256.
+	WindowSize int
+	// Store persists the follower's cursor across restarts. Defaults to
+	// an in-memory Store, which does not survive a restart.
+	Store Store
+}
+
+// Follower maintains a rolling, reorg-aware view of the chain and emits
+// typed events to a Handler.
+type Follower struct {
+	source Source
+	cfg    Config
+
+	mu        sync.Mutex
+	chain     []Block
+	finalized map[string]bool
+}
+
+// New returns a Follower that reads from source.
+func New(source Source, cfg Config) *Follower {
+	if cfg.WindowSize <= 0 {
+		cfg.WindowSize = defaultWindowSize
+	}
+	if cfg.Store == nil {
+		cfg.Store = NewMemoryStore()
+	}
+	return &Follower{
+		source:    source,
+		cfg:       cfg,
+		finalized: make(map[string]bool),
+	}
+}
+
+// Run back-fills from the stored cursor (if any) up to the current tip,
+// then subscribes for new blocks and confirmations, delivering events to
+// handler until ctx is cancelled or a subscription errors out.
+func (f *Follower) Run(ctx context.Context, handler Handler) error {
+	if err := f.backfill(ctx, handler); err != nil {
+		return err
+	}
+
+	newBlockErrs := f.source.SubscribeForNewBlocks(ctx)
+	confirmationErrs := f.source.SubscribeForBlockConfirmations(ctx)
+	blocks := f.source.Event("NewBlock").Response
+	confirmations := f.source.Event("BlockConfirmation").Response
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-newBlockErrs:
+			return fmt.Errorf("follower: new block subscription: %w", err)
+		case err := <-confirmationErrs:
+			return fmt.Errorf("follower: block confirmation subscription: %w", err)
+		case frame := <-blocks:
+			f.handleNewBlock(frame, handler)
+		case frame := <-confirmations:
+			f.handleConfirmation(frame, handler)
+		}
+	}
+}
+
+func (f *Follower) backfill(ctx context.Context, handler Handler) error {
+	cursor, hasCursor, err := f.cfg.Store.LoadCursor()
+	if err != nil {
+		return fmt.Errorf("follower: load cursor: %w", err)
+	}
+
+	chain, err := f.source.GetBestChainContext(ctx, f.cfg.WindowSize)
+	if err != nil {
+		return fmt.Errorf("follower: backfill: %w", err)
+	}
+
+	blocks := make([]Block, 0, len(chain))
+	for _, cb := range chain {
+		height, _ := strconv.ParseUint(cb.ProtocolState.ConsensusState.BlockHeight, 10, 64)
+		blocks = append(blocks, Block{
+			Height:     height,
+			Hash:       cb.StateHash,
+			ParentHash: cb.ProtocolState.PreviousStateHash,
+		})
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].Height < blocks[j].Height })
+
+	f.mu.Lock()
+	for _, b := range blocks {
+		if hasCursor && b.Height <= cursor.Height {
+			continue
+		}
+		f.chain = append(f.chain, b)
+	}
+	f.trimLocked()
+	toEmit := append([]Block(nil), f.chain...)
+	f.mu.Unlock()
+
+	for _, b := range toEmit {
+		handler(Event{Type: BlockAdded, Block: b})
+	}
+	return f.saveCursor()
+}
+
+func (f *Follower) handleNewBlock(frame *types.ResponseData, handler Handler) {
+	if frame == nil || frame.Data == nil {
+		return
+	}
+	var payload struct {
+		NewBlock struct {
+			StateHash         string `json:"stateHash"`
+			PreviousStateHash string `json:"previousStateHash"`
+			BlockchainLength  string `json:"blockchainLength"`
+		} `json:"newBlock"`
+	}
+	if err := json.Unmarshal(frame.Data.Payload, &payload); err != nil {
+		log.Println("follower: decode new block:", err)
+		return
+	}
+	height, _ := strconv.ParseUint(payload.NewBlock.BlockchainLength, 10, 64)
+	f.addBlock(Block{
+		Height:     height,
+		Hash:       payload.NewBlock.StateHash,
+		ParentHash: payload.NewBlock.PreviousStateHash,
+	}, handler)
+}
+
+// addBlock appends b to the tracked chain, first reverting back to the
+// point where the current tip's hash matches b.ParentHash — this is how a
+// reorg is detected and unwound, since the daemon simply reports whatever
+// new block it produced next. Comparing hashes rather than just heights is
+// what makes this correct for reorgs more than one block deep, and for a
+// competing block reported at the same height as the current tip.
+func (f *Follower) addBlock(b Block, handler Handler) {
+	f.mu.Lock()
+	for len(f.chain) > 0 && f.chain[len(f.chain)-1].Hash != b.ParentHash {
+		reverted := f.chain[len(f.chain)-1]
+		f.chain = f.chain[:len(f.chain)-1]
+		delete(f.finalized, reverted.Hash)
+		f.mu.Unlock()
+		handler(Event{Type: BlockReverted, Block: reverted})
+		f.mu.Lock()
+	}
+	f.chain = append(f.chain, b)
+	f.trimLocked()
+	f.mu.Unlock()
+
+	handler(Event{Type: BlockAdded, Block: b})
+	if err := f.saveCursor(); err != nil {
+		log.Println("follower: save cursor:", err)
+	}
+}
+
+func (f *Follower) handleConfirmation(frame *types.ResponseData, handler Handler) {
+	if frame == nil || frame.Data == nil {
+		return
+	}
+	var payload struct {
+		NewBlockConfirmation struct {
+			StateHash        string `json:"stateHash"`
+			NumConfirmations int    `json:"numConfirmations"`
+		} `json:"newBlockConfirmation"`
+	}
+	if err := json.Unmarshal(frame.Data.Payload, &payload); err != nil {
+		log.Println("follower: decode block confirmation:", err)
+		return
+	}
+	if uint64(payload.NewBlockConfirmation.NumConfirmations) < f.cfg.Confirmations {
+		return
+	}
+
+	hash := payload.NewBlockConfirmation.StateHash
+	f.mu.Lock()
+	if f.finalized[hash] {
+		f.mu.Unlock()
+		return
+	}
+	var block Block
+	found := false
+	for _, b := range f.chain {
+		if b.Hash == hash {
+			block, found = b, true
+			break
+		}
+	}
+	if found {
+		f.finalized[hash] = true
+	}
+	f.mu.Unlock()
+
+	if found {
+		handler(Event{Type: BlockFinalized, Block: block})
+	}
+}
+
+// trimLocked drops the oldest blocks once the chain exceeds WindowSize.
+// Callers must hold f.mu.
+func (f *Follower) trimLocked() {
+	if len(f.chain) <= f.cfg.WindowSize {
+		return
+	}
+	dropped := f.chain[:len(f.chain)-f.cfg.WindowSize]
+	for _, b := range dropped {
+		delete(f.finalized, b.Hash)
+	}
+	f.chain = f.chain[len(f.chain)-f.cfg.WindowSize:]
+}
+
+func (f *Follower) saveCursor() error {
+	f.mu.Lock()
+	if len(f.chain) == 0 {
+		f.mu.Unlock()
+		return nil
+	}
+	tip := f.chain[len(f.chain)-1]
+	f.mu.Unlock()
+	return f.cfg.Store.SaveCursor(Cursor{Height: tip.Height, Hash: tip.Hash})
+}
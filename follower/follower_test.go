@@ -0,0 +1,115 @@
+package follower
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestFollower() *Follower {
+	return New(nil, Config{WindowSize: 16})
+}
+
+func collectEvents(f *Follower, blocks []Block) []Event {
+	var events []Event
+	handler := func(e Event) { events = append(events, e) }
+	for _, b := range blocks {
+		f.addBlock(b, handler)
+	}
+	return events
+}
+
+func TestAddBlockExtendsChainWithoutReverts(t *testing.T) {
+	blocks := []Block{
+		{Height: 1, Hash: "a1", ParentHash: "genesis"},
+		{Height: 2, Hash: "a2", ParentHash: "a1"},
+		{Height: 3, Hash: "a3", ParentHash: "a2"},
+	}
+	f := newTestFollower()
+	events := collectEvents(f, blocks)
+
+	for _, e := range events {
+		if e.Type != BlockAdded {
+			t.Fatalf("unexpected event %+v, want only BlockAdded", e)
+		}
+	}
+	if len(events) != len(blocks) {
+		t.Fatalf("got %d events, want %d", len(events), len(blocks))
+	}
+}
+
+func TestAddBlockRevertsCompetingSiblingAtSameHeight(t *testing.T) {
+	f := newTestFollower()
+	var events []Event
+	handler := func(e Event) { events = append(events, e) }
+
+	f.addBlock(Block{Height: 1, Hash: "a1", ParentHash: "genesis"}, handler)
+	f.addBlock(Block{Height: 2, Hash: "a2", ParentHash: "a1"}, handler)
+	events = nil
+
+	// b2 is a sibling of a2: same height, but doesn't build on a2.
+	f.addBlock(Block{Height: 2, Hash: "b2", ParentHash: "a1"}, handler)
+
+	want := []Event{
+		{Type: BlockReverted, Block: Block{Height: 2, Hash: "a2", ParentHash: "a1"}},
+		{Type: BlockAdded, Block: Block{Height: 2, Hash: "b2", ParentHash: "a1"}},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("got %+v, want %+v", events, want)
+	}
+}
+
+func TestAddBlockUnwindsMultiBlockReorg(t *testing.T) {
+	f := newTestFollower()
+	var events []Event
+	handler := func(e Event) { events = append(events, e) }
+
+	f.addBlock(Block{Height: 1, Hash: "a1", ParentHash: "genesis"}, handler)
+	f.addBlock(Block{Height: 2, Hash: "a2", ParentHash: "a1"}, handler)
+	f.addBlock(Block{Height: 3, Hash: "a3", ParentHash: "a2"}, handler)
+	f.addBlock(Block{Height: 4, Hash: "a4", ParentHash: "a3"}, handler)
+	events = nil
+
+	// c3 builds on a2, not a3: a height-only check would only pop a4 and
+	// stop, leaving a3 (which c3 doesn't extend) in the chain.
+	f.addBlock(Block{Height: 3, Hash: "c3", ParentHash: "a2"}, handler)
+
+	want := []Event{
+		{Type: BlockReverted, Block: Block{Height: 4, Hash: "a4", ParentHash: "a3"}},
+		{Type: BlockReverted, Block: Block{Height: 3, Hash: "a3", ParentHash: "a2"}},
+		{Type: BlockAdded, Block: Block{Height: 3, Hash: "c3", ParentHash: "a2"}},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("got %+v, want %+v", events, want)
+	}
+
+	f.mu.Lock()
+	tip := f.chain[len(f.chain)-1]
+	f.mu.Unlock()
+	if tip.Hash != "c3" {
+		t.Fatalf("tip is %q, want c3", tip.Hash)
+	}
+}
+
+func TestAddBlockHigherNonExtendingBlockStillReverts(t *testing.T) {
+	f := newTestFollower()
+	var events []Event
+	handler := func(e Event) { events = append(events, e) }
+
+	f.addBlock(Block{Height: 1, Hash: "a1", ParentHash: "genesis"}, handler)
+	f.addBlock(Block{Height: 2, Hash: "a2", ParentHash: "a1"}, handler)
+	events = nil
+
+	// b3 is higher than the tip but doesn't build on it: a height-only
+	// check (tip.Height >= b.Height) would treat this as a plain extend
+	// and silently append it with no BlockReverted at all.
+	f.addBlock(Block{Height: 3, Hash: "b3", ParentHash: "b2"}, handler)
+
+	want := []Event{
+		{Type: BlockReverted, Block: Block{Height: 2, Hash: "a2", ParentHash: "a1"}},
+		{Type: BlockReverted, Block: Block{Height: 1, Hash: "a1", ParentHash: "genesis"}},
+		{Type: BlockAdded, Block: Block{Height: 3, Hash: "b3", ParentHash: "b2"}},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Fatalf("got %+v, want %+v", events, want)
+	}
+}
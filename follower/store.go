@@ -0,0 +1,47 @@
+package follower
+
+import "sync"
+
+// Cursor is the last chain position a Follower has durably processed.
+type Cursor struct {
+	Height uint64
+	Hash   string
+}
+
+// Store persists a Follower's Cursor so it can resume after a restart by
+// back-filling only what it missed, instead of re-deriving state from
+// genesis.
+type Store interface {
+	// LoadCursor returns the last saved Cursor. ok is false if nothing has
+	// been saved yet.
+	LoadCursor() (cursor Cursor, ok bool, err error)
+	SaveCursor(Cursor) error
+}
+
+// MemoryStore is a Store that keeps the cursor in memory only. It's the
+// default when no Store is configured, and is useful for tests and for
+// processes that don't need to survive restarts.
+type MemoryStore struct {
+	mu     sync.Mutex
+	cursor Cursor
+	has    bool
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) LoadCursor() (Cursor, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursor, s.has, nil
+}
+
+func (s *MemoryStore) SaveCursor(c Cursor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursor = c
+	s.has = true
+	return nil
+}
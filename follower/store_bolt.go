@@ -0,0 +1,59 @@
+package follower
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"go.etcd.io/bbolt"
+)
+
+var defaultBoltBucket = []byte("follower_cursor")
+
+const boltCursorKey = "cursor"
+
+// BoltStore persists the follower Cursor in a BoltDB bucket.
+type BoltStore struct {
+	db     *bbolt.DB
+	bucket []byte
+}
+
+// NewBoltStore opens (creating if needed) bucket in db for cursor storage.
+// An empty bucket name uses a sensible default.
+func NewBoltStore(db *bbolt.DB, bucket string) (*BoltStore, error) {
+	b := defaultBoltBucket
+	if bucket != "" {
+		b = []byte(bucket)
+	}
+	err := db.Update(func(tx *bbolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(b)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("follower: create bolt bucket: %w", err)
+	}
+	return &BoltStore{db: db, bucket: b}, nil
+}
+
+func (s *BoltStore) LoadCursor() (Cursor, bool, error) {
+	var cursor Cursor
+	var found bool
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(s.bucket).Get([]byte(boltCursorKey))
+		if v == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(v, &cursor)
+	})
+	return cursor, found, err
+}
+
+func (s *BoltStore) SaveCursor(c Cursor) error {
+	v, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("follower: marshal cursor: %w", err)
+	}
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(s.bucket).Put([]byte(boltCursorKey), v)
+	})
+}
@@ -0,0 +1,48 @@
+package follower
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SQLiteStore persists the follower Cursor in a single-row SQLite table.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// NewSQLiteStore migrates (if needed) and returns a cursor store backed by
+// db, which the caller owns and should open with the sqlite3 driver.
+func NewSQLiteStore(db *sql.DB) (*SQLiteStore, error) {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS follower_cursor (
+		id     INTEGER PRIMARY KEY CHECK (id = 0),
+		height INTEGER NOT NULL,
+		hash   TEXT NOT NULL
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("follower: migrate sqlite store: %w", err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+func (s *SQLiteStore) LoadCursor() (Cursor, bool, error) {
+	var cursor Cursor
+	row := s.db.QueryRow(`SELECT height, hash FROM follower_cursor WHERE id = 0`)
+	switch err := row.Scan(&cursor.Height, &cursor.Hash); err {
+	case nil:
+		return cursor, true, nil
+	case sql.ErrNoRows:
+		return cursor, false, nil
+	default:
+		return cursor, false, err
+	}
+}
+
+func (s *SQLiteStore) SaveCursor(c Cursor) error {
+	_, err := s.db.Exec(`
+		INSERT INTO follower_cursor (id, height, hash) VALUES (0, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET height = excluded.height, hash = excluded.hash`,
+		c.Height, c.Hash)
+	return err
+}
@@ -0,0 +1,60 @@
+package hub
+
+import (
+	"encoding/json"
+
+	"github.com/spdd/coda-go-client/client/types"
+)
+
+// Filter reports whether a ResponseData frame should be delivered to a
+// given subscriber. It is evaluated server-side, before broadcast, so a
+// subscriber only ever sees the frames it asked for.
+type Filter func(*types.ResponseData) bool
+
+// ByPublicKey keeps only frames whose decoded payload has a "publicKey"
+// field equal to the given public key, at any depth, e.g. block
+// confirmations for a specific block producer.
+func ByPublicKey(publicKey string) Filter {
+	return func(d *types.ResponseData) bool {
+		return payloadHasField(d, "publicKey", publicKey)
+	}
+}
+
+// ByAddress keeps only payment frames to or from the given address.
+func ByAddress(address string) Filter {
+	return func(d *types.ResponseData) bool {
+		return payloadHasField(d, "from", address) || payloadHasField(d, "to", address)
+	}
+}
+
+func payloadHasField(d *types.ResponseData, key, want string) bool {
+	if d == nil || d.Data == nil || d.Data.Payload == nil {
+		return false
+	}
+	var generic interface{}
+	if err := json.Unmarshal(d.Data.Payload, &generic); err != nil {
+		return false
+	}
+	return fieldEquals(generic, key, want)
+}
+
+func fieldEquals(v interface{}, key, want string) bool {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		if s, ok := t[key].(string); ok && s == want {
+			return true
+		}
+		for _, nested := range t {
+			if fieldEquals(nested, key, want) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, item := range t {
+			if fieldEquals(item, key, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+// Package hub fans out subscription data from one or more coda Clients to
+// any number of downstream consumers, with bounded per-subscriber channels
+// so a slow consumer can't stall the others.
+package hub
+
+import (
+	"context"
+	"sync"
+
+	"github.com/spdd/coda-go-client/client/types"
+)
+
+// Policy controls what happens when a subscriber's channel is full.
+type Policy int
+
+const (
+	// PolicyDropOldest discards the subscriber's oldest buffered frame to
+	// make room for the new one.
+	PolicyDropOldest Policy = iota
+	// PolicyDisconnect closes the subscriber's channel and removes it.
+	PolicyDisconnect
+)
+
+// defaultOutChannelSize is used when a Hub is constructed with a
+// non-positive size.
+const defaultOutChannelSize = 64
+
+type subscription struct {
+	ch     chan *types.ResponseData
+	filter Filter
+}
+
+// Hub fans out ResponseData pushed onto SubscriptionData (typically by one
+// or more coda Client instances) to any number of Subscribe'd consumers.
+type Hub struct {
+	// SubscriptionData is the inbound side of the Hub: Clients push the
+	// ResponseData from their active subscriptions onto this channel.
+	SubscriptionData chan *types.ResponseData
+
+	outChannelSize int
+	policy         Policy
+
+	mu          sync.Mutex
+	subscribers map[uint64]*subscription
+	nextID      uint64
+}
+
+// New creates a Hub whose per-subscriber channels hold outChannelSize
+// frames before policy kicks in.
+func New(outChannelSize int, policy Policy) *Hub {
+	if outChannelSize <= 0 {
+		outChannelSize = defaultOutChannelSize
+	}
+	return &Hub{
+		SubscriptionData: make(chan *types.ResponseData, outChannelSize),
+		outChannelSize:   outChannelSize,
+		policy:           policy,
+		subscribers:      make(map[uint64]*subscription),
+	}
+}
+
+// Run reads from SubscriptionData and broadcasts to every current
+// subscriber until ctx is cancelled or SubscriptionData is closed.
+func (h *Hub) Run(ctx context.Context) {
+	for {
+		select {
+		case data, ok := <-h.SubscriptionData:
+			if !ok {
+				return
+			}
+			h.broadcast(data)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (h *Hub) broadcast(data *types.ResponseData) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for id, sub := range h.subscribers {
+		if sub.filter != nil && !sub.filter(data) {
+			continue
+		}
+		select {
+		case sub.ch <- data:
+			continue
+		default:
+		}
+
+		switch h.policy {
+		case PolicyDropOldest:
+			select {
+			case <-sub.ch:
+			default:
+			}
+			select {
+			case sub.ch <- data:
+			default:
+			}
+		case PolicyDisconnect:
+			close(sub.ch)
+			delete(h.subscribers, id)
+		}
+	}
+}
+
+// Subscribe registers a new consumer, optionally restricted to frames
+// matching filter (nil means "everything"), and returns its id plus the
+// channel it will be delivered on. Unsubscribe(id) stops delivery and
+// closes the channel.
+func (h *Hub) Subscribe(filter Filter) (id uint64, data <-chan *types.ResponseData) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.nextID++
+	id = h.nextID
+	ch := make(chan *types.ResponseData, h.outChannelSize)
+	h.subscribers[id] = &subscription{ch: ch, filter: filter}
+	return id, ch
+}
+
+// Unsubscribe removes a subscriber and closes its channel. It is a no-op
+// if id is not (or is no longer) registered.
+func (h *Hub) Unsubscribe(id uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if sub, ok := h.subscribers[id]; ok {
+		close(sub.ch)
+		delete(h.subscribers, id)
+	}
+}
@@ -0,0 +1,61 @@
+package hub
+
+import (
+	"testing"
+
+	"github.com/spdd/coda-go-client/client/types"
+)
+
+func TestBroadcastDropsOldestWhenSubscriberChannelIsFull(t *testing.T) {
+	h := New(2, PolicyDropOldest)
+	id, ch := h.Subscribe(nil)
+	defer h.Unsubscribe(id)
+
+	for i := 0; i < 5; i++ {
+		h.broadcast(&types.ResponseData{Type: "NewBlock"})
+	}
+
+	if got := len(ch); got != 2 {
+		t.Fatalf("subscriber has %d buffered frames, want 2 (full, never blocked)", got)
+	}
+	if _, stillSubscribed := h.subscribers[id]; !stillSubscribed {
+		t.Fatal("PolicyDropOldest should never disconnect the subscriber")
+	}
+}
+
+func TestBroadcastDisconnectsSubscriberWhenChannelIsFull(t *testing.T) {
+	h := New(1, PolicyDisconnect)
+	id, ch := h.Subscribe(nil)
+
+	h.broadcast(&types.ResponseData{Type: "NewBlock"}) // fills the single slot
+	h.broadcast(&types.ResponseData{Type: "NewBlock"}) // channel is full: should disconnect
+
+	h.mu.Lock()
+	_, stillSubscribed := h.subscribers[id]
+	h.mu.Unlock()
+	if stillSubscribed {
+		t.Fatal("subscriber should have been disconnected once its channel filled")
+	}
+
+	<-ch // the one frame that made it in before the disconnect
+	if _, ok := <-ch; ok {
+		t.Fatal("subscriber's channel should be closed after PolicyDisconnect")
+	}
+}
+
+func TestBroadcastSkipsSubscribersFilteredOut(t *testing.T) {
+	h := New(4, PolicyDropOldest)
+	_, matching := h.Subscribe(ByAddress("alice"))
+	_, nonMatching := h.Subscribe(ByAddress("bob"))
+
+	h.broadcast(&types.ResponseData{
+		Data: &types.SubscriptionResponse{Payload: []byte(`{"from":"alice","to":"carol"}`)},
+	})
+
+	if len(matching) != 1 {
+		t.Fatalf("matching subscriber got %d frames, want 1", len(matching))
+	}
+	if len(nonMatching) != 0 {
+		t.Fatalf("non-matching subscriber got %d frames, want 0", len(nonMatching))
+	}
+}
@@ -0,0 +1,180 @@
+package hub
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Server exposes a Hub over a small JSON-RPC/WebSocket dialect modeled on
+// eth_subscribe: a client calls coda_subscribe("newBlocks" | "payments",
+// [filterArg]) to open a subscription and receives SubscriptionNotification
+// frames until it calls coda_unsubscribe(id). The channel name only selects
+// how filterArg is read (a public key for "newBlocks", an address for
+// "payments"); frames are not filtered by their type, so a subscription
+// receives every Hub frame that passes its filter, or every frame when no
+// filterArg is given.
+type Server struct {
+	hub      *Hub
+	upgrader websocket.Upgrader
+}
+
+// NewServer returns a Server that re-publishes h's fan-out over WebSocket.
+func NewServer(h *Hub) *Server {
+	return &Server{hub: h}
+}
+
+// ServeHTTP implements net/http.Handler by upgrading the connection to a
+// WebSocket and serving the JSON-RPC dialect over it until the client
+// disconnects.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	c := &rpcConn{conn: conn, subs: make(map[uint64]func())}
+	defer c.close()
+
+	for {
+		var req rpcRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+		c.handle(s.hub, req)
+	}
+}
+
+type rpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Result interface{}     `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// SubscriptionNotification is a single fanned-out frame pushed to a
+// JSON-RPC/WebSocket client for one of its active subscriptions.
+type SubscriptionNotification struct {
+	Method string `json:"method"`
+	Params struct {
+		Subscription uint64      `json:"subscription"`
+		Result       interface{} `json:"result"`
+	} `json:"params"`
+}
+
+// rpcConn tracks one JSON-RPC/WebSocket connection's active subscriptions
+// so they can all be torn down when the connection closes.
+type rpcConn struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+	subs map[uint64]func()
+}
+
+func (c *rpcConn) writeJSON(v interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn.WriteJSON(v)
+}
+
+func (c *rpcConn) close() {
+	c.mu.Lock()
+	cancels := make([]func(), 0, len(c.subs))
+	for _, cancel := range c.subs {
+		cancels = append(cancels, cancel)
+	}
+	c.subs = nil
+	c.mu.Unlock()
+	for _, cancel := range cancels {
+		cancel()
+	}
+	c.conn.Close()
+}
+
+func (c *rpcConn) handle(h *Hub, req rpcRequest) {
+	switch req.Method {
+	case "coda_subscribe":
+		c.subscribe(h, req)
+	case "coda_unsubscribe":
+		c.unsubscribe(req)
+	default:
+		c.writeJSON(rpcResponse{ID: req.ID, Error: "unknown method: " + req.Method})
+	}
+}
+
+func (c *rpcConn) subscribe(h *Hub, req rpcRequest) {
+	var channel string
+	if len(req.Params) > 0 {
+		json.Unmarshal(req.Params[0], &channel)
+	}
+
+	var filter Filter
+	if len(req.Params) > 1 {
+		var arg string
+		if err := json.Unmarshal(req.Params[1], &arg); err == nil && arg != "" {
+			switch channel {
+			case "newBlocks":
+				filter = ByPublicKey(arg)
+			case "payments":
+				filter = ByAddress(arg)
+			}
+		}
+	}
+
+	id, data := h.Subscribe(filter)
+	done := make(chan struct{})
+
+	c.mu.Lock()
+	if c.subs == nil {
+		h.Unsubscribe(id)
+		c.mu.Unlock()
+		return
+	}
+	c.subs[id] = func() {
+		close(done)
+		h.Unsubscribe(id)
+	}
+	c.mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case frame, ok := <-data:
+				if !ok {
+					return
+				}
+				notification := SubscriptionNotification{Method: "coda_subscription"}
+				notification.Params.Subscription = id
+				notification.Params.Result = frame
+				c.writeJSON(notification)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	c.writeJSON(rpcResponse{ID: req.ID, Result: id})
+}
+
+func (c *rpcConn) unsubscribe(req rpcRequest) {
+	var id uint64
+	if len(req.Params) > 0 {
+		json.Unmarshal(req.Params[0], &id)
+	}
+
+	c.mu.Lock()
+	cancel, ok := c.subs[id]
+	if ok {
+		delete(c.subs, id)
+	}
+	c.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	c.writeJSON(rpcResponse{ID: req.ID, Result: ok})
+}
@@ -0,0 +1,84 @@
+package payments
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NanoMinaPerMina is the number of nanomina in one Mina, Mina's smallest
+// and canonical on-chain units respectively.
+const NanoMinaPerMina = 1_000_000_000
+
+// Mina is a decimal amount of the Mina currency, stored internally as an
+// exact integer count of nanomina to avoid floating-point rounding.
+type Mina struct {
+	nanoMina int64
+}
+
+// MinaFromNanoMina wraps a raw nanomina amount as a Mina.
+func MinaFromNanoMina(nanoMina int64) Mina {
+	return Mina{nanoMina: nanoMina}
+}
+
+// ParseMina parses a decimal string, e.g. "1.5", as a Mina amount. It
+// supports up to nine fractional digits (Mina's full precision); more are
+// rejected rather than silently rounded.
+func ParseMina(s string) (Mina, error) {
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+
+	whole, frac, hasFrac := strings.Cut(s, ".")
+	if whole == "" {
+		whole = "0"
+	}
+	wholeN, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return Mina{}, fmt.Errorf("payments: invalid mina amount %q: %w", s, err)
+	}
+
+	var fracN int64
+	if hasFrac {
+		if len(frac) > 9 {
+			return Mina{}, fmt.Errorf("payments: invalid mina amount %q: more than 9 fractional digits", s)
+		}
+		frac = frac + strings.Repeat("0", 9-len(frac))
+		fracN, err = strconv.ParseInt(frac, 10, 64)
+		if err != nil {
+			return Mina{}, fmt.Errorf("payments: invalid mina amount %q: %w", s, err)
+		}
+	}
+
+	amount := wholeN*NanoMinaPerMina + fracN
+	if neg {
+		amount = -amount
+	}
+	return Mina{nanoMina: amount}, nil
+}
+
+// NanoMina returns m as a raw nanomina integer, the unit SendPaymentContext
+// expects.
+func (m Mina) NanoMina() int64 {
+	return m.nanoMina
+}
+
+// String formats m as a decimal Mina amount, e.g. "1.5".
+func (m Mina) String() string {
+	neg := m.nanoMina < 0
+	n := m.nanoMina
+	if neg {
+		n = -n
+	}
+	whole := n / NanoMinaPerMina
+	frac := n % NanoMinaPerMina
+	s := fmt.Sprintf("%d.%09d", whole, frac)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if neg {
+		s = "-" + s
+	}
+	return s
+}
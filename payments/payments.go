@@ -0,0 +1,286 @@
+// Package payments provides a higher-level payment builder on top of
+// Client: Mina-denominated amounts, wallet unlocking via a Keyring, fee
+// estimation, idempotency, retry on transient errors, and a handle that
+// waits for a chosen confirmation depth.
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/spdd/coda-go-client/client/types"
+)
+
+// MaxMemoBytes is the daemon's memo field size limit; idempotency keys
+// longer than this are truncated.
+const MaxMemoBytes = 32
+
+const (
+	defaultFee         = 10_000_000 // 0.01 Mina, used if the daemon reports no fee stats
+	defaultMaxRetries  = 3
+	statusPollInterval = 2 * time.Second
+)
+
+// Keyring supplies the password needed to unlock a wallet before it can
+// send a payment.
+type Keyring interface {
+	Password(ctx context.Context, publicKey string) (string, error)
+}
+
+// Source is the subset of Client a Service needs. It is satisfied by
+// *coda.Client.
+type Source interface {
+	UnlockWalletContext(ctx context.Context, pk, password string) (*types.Wallet, error)
+	GetDaemonStatusContext(ctx context.Context) (*types.DaemonStatus, error)
+	SendPaymentContext(ctx context.Context, from, to string, amount, fee int, memo string) (*types.Payment, error)
+	GetPooledPaymentsContext(ctx context.Context, pk string) ([]types.PooledPayment, error)
+	GetTransactionStatusContext(ctx context.Context, paymentId string) (types.TransactionStatus, error)
+	SubscribeForBlockConfirmations(ctx context.Context) <-chan error
+	Event(t string) *types.Event
+}
+
+// PaymentRequest describes a single payment to send. Fee is optional; a
+// nil Fee is estimated from the daemon's current fee stats. IdempotencyKey
+// is stored in the payment's memo; send checks the pool for an existing
+// payment with the same memo before submitting, so calling Send/SendPayments
+// again with the same request after a prior attempt (or one that merely
+// looked like it failed) won't double-send while that earlier payment is
+// still in the pool. Once it has been included in a block it is no longer
+// found, and a repeated request is sent again.
+type PaymentRequest struct {
+	From           string
+	To             string
+	Amount         Mina
+	Fee            *Mina
+	IdempotencyKey string
+}
+
+// PendingPayment is a handle to a submitted payment that hasn't necessarily
+// been included in a block yet.
+type PendingPayment struct {
+	Payment *types.Payment
+	source  Source
+}
+
+// Wait blocks until the payment has been included and confirmed to at
+// least the given depth, as reported by the daemon's block confirmation
+// subscription, or until ctx is done.
+func (p *PendingPayment) Wait(ctx context.Context, confirmations int) error {
+	for {
+		status, err := p.source.GetTransactionStatusContext(ctx, p.Payment.ID)
+		if err != nil {
+			return fmt.Errorf("payments: transaction status: %w", err)
+		}
+		if status == types.TransactionStatusIncluded {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(statusPollInterval):
+		}
+	}
+
+	if confirmations <= 0 {
+		return nil
+	}
+
+	errs := p.source.SubscribeForBlockConfirmations(ctx)
+	confirmationFrames := p.source.Event("BlockConfirmation").Response
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-errs:
+			return fmt.Errorf("payments: block confirmation subscription: %w", err)
+		case frame := <-confirmationFrames:
+			if depth, ok := confirmationDepth(frame); ok && depth >= confirmations {
+				return nil
+			}
+		}
+	}
+}
+
+func confirmationDepth(frame *types.ResponseData) (int, bool) {
+	if frame == nil || frame.Data == nil || frame.Data.Payload == nil {
+		return 0, false
+	}
+	var payload struct {
+		NewBlockConfirmation struct {
+			NumConfirmations int `json:"numConfirmations"`
+		} `json:"newBlockConfirmation"`
+	}
+	if err := json.Unmarshal(frame.Data.Payload, &payload); err != nil {
+		return 0, false
+	}
+	return payload.NewBlockConfirmation.NumConfirmations, true
+}
+
+// Service is the Payments subsystem itself: it turns PaymentRequests into
+// submitted, trackable payments.
+type Service struct {
+	source     Source
+	keyring    Keyring
+	maxRetries int
+}
+
+// NewService returns a Service that unlocks wallets via keyring and sends
+// payments through source.
+func NewService(source Source, keyring Keyring) *Service {
+	return &Service{source: source, keyring: keyring, maxRetries: defaultMaxRetries}
+}
+
+// Send unlocks req.From, estimates a fee if req.Fee is nil, and submits
+// the payment, retrying on transient GraphQL errors.
+func (s *Service) Send(ctx context.Context, req PaymentRequest) (*PendingPayment, error) {
+	if err := s.unlock(ctx, req.From); err != nil {
+		return nil, err
+	}
+	return s.send(ctx, req)
+}
+
+// SendPayments sends every request, unlocking each distinct From wallet at
+// most once even if several requests share it.
+func (s *Service) SendPayments(ctx context.Context, reqs []PaymentRequest) ([]*PendingPayment, error) {
+	unlocked := make(map[string]bool, len(reqs))
+	results := make([]*PendingPayment, len(reqs))
+	for i, req := range reqs {
+		if !unlocked[req.From] {
+			if err := s.unlock(ctx, req.From); err != nil {
+				return results, fmt.Errorf("payments: unlock %s: %w", req.From, err)
+			}
+			unlocked[req.From] = true
+		}
+		pending, err := s.send(ctx, req)
+		if err != nil {
+			return results, fmt.Errorf("payments: send request %d: %w", i, err)
+		}
+		results[i] = pending
+	}
+	return results, nil
+}
+
+func (s *Service) unlock(ctx context.Context, publicKey string) error {
+	password, err := s.keyring.Password(ctx, publicKey)
+	if err != nil {
+		return fmt.Errorf("payments: keyring password for %s: %w", publicKey, err)
+	}
+	if _, err := s.source.UnlockWalletContext(ctx, publicKey, password); err != nil {
+		return fmt.Errorf("payments: unlock wallet %s: %w", publicKey, err)
+	}
+	return nil
+}
+
+func (s *Service) send(ctx context.Context, req PaymentRequest) (*PendingPayment, error) {
+	memo := req.IdempotencyKey
+	if len(memo) > MaxMemoBytes {
+		memo = memo[:MaxMemoBytes]
+	}
+
+	if memo != "" {
+		existing, err := s.findPending(ctx, req.From, memo)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return &PendingPayment{Payment: existing, source: s.source}, nil
+		}
+	}
+
+	fee, err := s.resolveFee(ctx, req.Fee)
+	if err != nil {
+		return nil, err
+	}
+
+	var payment *types.Payment
+	err = withRetry(ctx, s.maxRetries, func() error {
+		p, sendErr := s.source.SendPaymentContext(ctx, req.From, req.To, int(req.Amount.NanoMina()), fee, memo)
+		if sendErr != nil {
+			// The daemon may have accepted the payment even though this
+			// call errored out (e.g. the response timed out after the
+			// mutation landed). Check before withRetry resubmits, or a
+			// transient error here turns into a genuine double-send.
+			if memo != "" {
+				if existing, findErr := s.findPending(ctx, req.From, memo); findErr == nil && existing != nil {
+					payment = existing
+					return nil
+				}
+			}
+			return sendErr
+		}
+		payment = p
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("payments: send payment: %w", err)
+	}
+	return &PendingPayment{Payment: payment, source: s.source}, nil
+}
+
+// findPending looks for a payment from publicKey already sitting in the
+// daemon's transaction pool with the given memo, so send can recognize a
+// request it (or an earlier, failed-looking attempt at it) already
+// submitted instead of resubmitting it. It only sees pooled payments, not
+// ones already included in a block; GetTransactionStatusContext has no way
+// to look a payment up by memo, only by the ID send already has.
+func (s *Service) findPending(ctx context.Context, publicKey, memo string) (*types.Payment, error) {
+	pooled, err := s.source.GetPooledPaymentsContext(ctx, publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("payments: check pending payments: %w", err)
+	}
+	for _, p := range pooled {
+		if p.Memo == memo {
+			return &types.Payment{ID: p.ID, Nonce: p.Nonce}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Service) resolveFee(ctx context.Context, fee *Mina) (int, error) {
+	if fee != nil {
+		return int(fee.NanoMina()), nil
+	}
+	status, err := s.source.GetDaemonStatusContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("payments: estimate fee: %w", err)
+	}
+	if status == nil || status.TxnFeesStats == nil || status.TxnFeesStats.Median == "" {
+		return defaultFee, nil
+	}
+	median, err := strconv.ParseInt(status.TxnFeesStats.Median, 10, 64)
+	if err != nil {
+		return defaultFee, nil
+	}
+	return int(median), nil
+}
+
+// withRetry calls fn, retrying with exponential backoff and jitter on
+// GraphQL-level errors (types.GraphQLErrors) up to maxRetries times.
+// Transport errors are not retried, since they usually indicate a problem
+// retrying won't fix.
+func withRetry(ctx context.Context, maxRetries int, fn func() error) error {
+	var err error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if _, transient := err.(types.GraphQLErrors); !transient {
+			return err
+		}
+		if attempt == maxRetries {
+			break
+		}
+		delay := time.Duration(1<<uint(attempt)) * 250 * time.Millisecond
+		delay += time.Duration(rand.Int63n(int64(delay) / 2))
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
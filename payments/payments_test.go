@@ -0,0 +1,166 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spdd/coda-go-client/client/types"
+)
+
+// fakeSource is a minimal, in-memory Source used to drive Service without a
+// real daemon.
+type fakeSource struct {
+	sendErrs     []error // consumed in order by each SendPaymentContext call
+	sendCalls    int
+	unlockCalls  map[string]int
+	pooled       []types.PooledPayment
+	nextPayment  int
+	daemonStatus *types.DaemonStatus
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{unlockCalls: make(map[string]int)}
+}
+
+func (f *fakeSource) UnlockWalletContext(ctx context.Context, pk, password string) (*types.Wallet, error) {
+	f.unlockCalls[pk]++
+	return &types.Wallet{PublicKey: pk}, nil
+}
+
+func (f *fakeSource) GetDaemonStatusContext(ctx context.Context) (*types.DaemonStatus, error) {
+	if f.daemonStatus != nil {
+		return f.daemonStatus, nil
+	}
+	return &types.DaemonStatus{}, nil
+}
+
+func (f *fakeSource) SendPaymentContext(ctx context.Context, from, to string, amount, fee int, memo string) (*types.Payment, error) {
+	defer func() { f.sendCalls++ }()
+	if f.sendCalls < len(f.sendErrs) {
+		if err := f.sendErrs[f.sendCalls]; err != nil {
+			return nil, err
+		}
+	}
+	f.nextPayment++
+	p := &types.Payment{ID: "payment-id", Nonce: "1"}
+	f.pooled = append(f.pooled, types.PooledPayment{ID: p.ID, From: from, To: to, Memo: memo, Nonce: p.Nonce})
+	return p, nil
+}
+
+func (f *fakeSource) GetPooledPaymentsContext(ctx context.Context, pk string) ([]types.PooledPayment, error) {
+	var out []types.PooledPayment
+	for _, p := range f.pooled {
+		if p.From == pk {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSource) GetTransactionStatusContext(ctx context.Context, paymentId string) (types.TransactionStatus, error) {
+	return types.TransactionStatusPending, nil
+}
+
+func (f *fakeSource) SubscribeForBlockConfirmations(ctx context.Context) <-chan error {
+	return make(chan error)
+}
+
+func (f *fakeSource) Event(t string) *types.Event {
+	return &types.Event{Response: make(chan *types.ResponseData)}
+}
+
+func TestSendSkipsResubmitWhenAlreadyPooled(t *testing.T) {
+	src := newFakeSource()
+	src.pooled = []types.PooledPayment{
+		{ID: "existing-id", From: "alice", Memo: "req-1", Nonce: "5"},
+	}
+	svc := NewService(src, nil)
+
+	pending, err := svc.send(context.Background(), PaymentRequest{
+		From: "alice", To: "bob", Amount: MinaFromNanoMina(1), IdempotencyKey: "req-1",
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if pending.Payment.ID != "existing-id" {
+		t.Fatalf("got payment %q, want the already-pooled existing-id", pending.Payment.ID)
+	}
+	if src.sendCalls != 0 {
+		t.Fatalf("SendPaymentContext called %d times, want 0", src.sendCalls)
+	}
+}
+
+func TestSendRetriesTransientGraphQLErrors(t *testing.T) {
+	src := newFakeSource()
+	src.sendErrs = []error{
+		types.GraphQLErrors{{Message: "timeout"}},
+		types.GraphQLErrors{{Message: "timeout again"}},
+	}
+	svc := NewService(src, nil)
+
+	pending, err := svc.send(context.Background(), PaymentRequest{
+		From: "alice", To: "bob", Amount: MinaFromNanoMina(1), IdempotencyKey: "req-2",
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if pending.Payment.ID != "payment-id" {
+		t.Fatalf("got payment %q, want payment-id", pending.Payment.ID)
+	}
+	if src.sendCalls != 3 {
+		t.Fatalf("SendPaymentContext called %d times, want 3 (2 failures + 1 success)", src.sendCalls)
+	}
+}
+
+func TestSendDoesNotRetryNonGraphQLErrors(t *testing.T) {
+	src := newFakeSource()
+	src.sendErrs = []error{errors.New("connection reset")}
+	svc := NewService(src, nil)
+
+	_, err := svc.send(context.Background(), PaymentRequest{
+		From: "alice", To: "bob", Amount: MinaFromNanoMina(1), IdempotencyKey: "req-3",
+	})
+	if err == nil {
+		t.Fatal("send: expected an error")
+	}
+	if src.sendCalls != 1 {
+		t.Fatalf("SendPaymentContext called %d times, want 1 (no retry on a non-GraphQL error)", src.sendCalls)
+	}
+}
+
+// fakeSourceWithGhostAccept models a daemon that accepted a payment (it's
+// in the pool) but whose SendPaymentContext response still comes back as a
+// transient error, to verify send checks the pool before withRetry
+// resubmits.
+type fakeSourceWithGhostAccept struct {
+	*fakeSource
+	acceptedOnce bool
+}
+
+func (f *fakeSourceWithGhostAccept) SendPaymentContext(ctx context.Context, from, to string, amount, fee int, memo string) (*types.Payment, error) {
+	if !f.acceptedOnce {
+		f.acceptedOnce = true
+		f.pooled = append(f.pooled, types.PooledPayment{ID: "ghost-id", From: from, To: to, Memo: memo, Nonce: "1"})
+		return nil, types.GraphQLErrors{{Message: "deadline exceeded"}}
+	}
+	return f.fakeSource.SendPaymentContext(ctx, from, to, amount, fee, memo)
+}
+
+func TestSendDoesNotDoubleSendWhenDaemonAcceptedDespiteError(t *testing.T) {
+	src := &fakeSourceWithGhostAccept{fakeSource: newFakeSource()}
+	svc := NewService(src, nil)
+
+	pending, err := svc.send(context.Background(), PaymentRequest{
+		From: "alice", To: "bob", Amount: MinaFromNanoMina(1), IdempotencyKey: "req-5",
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if pending.Payment.ID != "ghost-id" {
+		t.Fatalf("got payment %q, want ghost-id (found via pool, not resubmitted)", pending.Payment.ID)
+	}
+	if src.fakeSource.sendCalls != 0 {
+		t.Fatalf("fakeSource.SendPaymentContext called %d times, want 0 (only the ghost-accept path ran)", src.fakeSource.sendCalls)
+	}
+}